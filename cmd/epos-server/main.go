@@ -0,0 +1,368 @@
+// Command epos-server speaks a subset of the Epson ePOS-Print XML protocol
+// over HTTP(S), translating <epos-print> documents into calls on an
+// escpos.Escpos. It lets web-based POS front-ends that already target Epson
+// printers drive any printer this module supports.
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/March-deng/goesc/escpos"
+)
+
+// device is a single printer registered under a devid.
+type device struct {
+	mu sync.Mutex
+	e  *escpos.Escpos
+}
+
+// server dispatches ePOS-Print requests to registered devices.
+type server struct {
+	mu      sync.Mutex
+	devices map[string]*device
+}
+
+func newServer() *server {
+	return &server{devices: make(map[string]*device)}
+}
+
+// register makes a device reachable at /cgi-bin/epos/service.cgi?devid=id,
+// talking ESC/POS over conn.
+func (s *server) register(id string, conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.devices[id] = &device{e: escpos.New(conn)}
+}
+
+func (s *server) device(id string) (*device, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.devices[id]
+	return d, ok
+}
+
+// The following types decode a single <epos-print> child element each, via
+// xml.Decoder.DecodeElement. decodeCommands decodes every element first, in
+// the order they appear in the document, and serviceHandler then runs the
+// resulting commands in that same order.
+type textElem struct {
+	Value string `xml:",chardata"`
+}
+
+type feedElem struct {
+	Line int `xml:"line,attr"`
+}
+
+type cutElem struct {
+	Type string `xml:"type,attr"`
+}
+
+type barcodeElem struct {
+	Type string `xml:"type,attr"`
+	Data string `xml:",chardata"`
+}
+
+type symbolElem struct {
+	Type string `xml:"type,attr"`
+	Data string `xml:",chardata"`
+}
+
+type pulseElem struct{}
+
+// imageElem is a base64-encoded 1bpp raster, width and height in dots, e.g.
+// <image width="203" height="48">base64...</image>
+type imageElem struct {
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+	Data   string `xml:",chardata"`
+}
+
+// printCmd is a single, already-validated element ready to run against a
+// printer; its error return is therefore always an execution/I/O failure,
+// never a decode error. Splitting decode (which can fail on malformed input)
+// from run lets serviceHandler validate the whole document before writing
+// anything to the printer.
+type printCmd func(e *escpos.Escpos) error
+
+// elementBuilders maps an element name to a builder that decodes one
+// element of that type (consuming up to its matching end tag) and returns
+// the printCmd it represents.
+var elementBuilders = map[string]func(dec *xml.Decoder, start xml.StartElement) (printCmd, error){
+	"text": func(dec *xml.Decoder, start xml.StartElement) (printCmd, error) {
+		var el textElem
+		if err := dec.DecodeElement(&el, &start); err != nil {
+			return nil, err
+		}
+		return func(e *escpos.Escpos) error { e.Write(el.Value); return nil }, nil
+	},
+	"feed": func(dec *xml.Decoder, start xml.StartElement) (printCmd, error) {
+		var el feedElem
+		if err := dec.DecodeElement(&el, &start); err != nil {
+			return nil, err
+		}
+		n := el.Line
+		if n <= 0 {
+			n = 1
+		}
+		return func(e *escpos.Escpos) error { e.FormfeedN(n); return nil }, nil
+	},
+	"cut": func(dec *xml.Decoder, start xml.StartElement) (printCmd, error) {
+		var el cutElem
+		if err := dec.DecodeElement(&el, &start); err != nil {
+			return nil, err
+		}
+		return func(e *escpos.Escpos) error { e.Cut(); return nil }, nil
+	},
+	"barcode": func(dec *xml.Decoder, start xml.StartElement) (printCmd, error) {
+		var el barcodeElem
+		if err := dec.DecodeElement(&el, &start); err != nil {
+			return nil, err
+		}
+		format := barcodeFormat(el.Type)
+		return func(e *escpos.Escpos) error { e.Barcode(el.Data, format); return nil }, nil
+	},
+	"symbol": func(dec *xml.Decoder, start xml.StartElement) (printCmd, error) {
+		var el symbolElem
+		if err := dec.DecodeElement(&el, &start); err != nil {
+			return nil, err
+		}
+		return func(e *escpos.Escpos) error {
+			switch el.Type {
+			case "pdf417":
+				e.PDF417(el.Data, 0, 0)
+			case "datamatrix":
+				e.DataMatrix(el.Data, 4)
+			default: // "qrcode" and unrecognized types fall back to QR
+				e.QRCode(el.Data, 2, 4, escpos.QRErrorCorrectionM)
+			}
+			return nil
+		}, nil
+	},
+	"image": func(dec *xml.Decoder, start xml.StartElement) (printCmd, error) {
+		var el imageElem
+		if err := dec.DecodeElement(&el, &start); err != nil {
+			return nil, err
+		}
+		raw, err := base64.StdEncoding.DecodeString(el.Data)
+		if err != nil {
+			return nil, fmt.Errorf("epos-server: decode image data: %w", err)
+		}
+		img, err := decodeMonoRaster(raw, el.Width, el.Height)
+		if err != nil {
+			return nil, err
+		}
+		// the raster is already 1bpp black/white; DitherThreshold passes it
+		// straight through instead of re-dithering already-binary pixels.
+		return func(e *escpos.Escpos) error {
+			return e.Image(img, escpos.ImageOptions{Dither: escpos.DitherThreshold})
+		}, nil
+	},
+	"pulse": func(dec *xml.Decoder, start xml.StartElement) (printCmd, error) {
+		var el pulseElem
+		if err := dec.DecodeElement(&el, &start); err != nil {
+			return nil, err
+		}
+		return func(e *escpos.Escpos) error { e.Pulse(); return nil }, nil
+	},
+}
+
+// decodeCommands walks dec in document order, decoding each recognized
+// child element of the root into a printCmd. It returns an error (without
+// running anything) on the first malformed element, so a bad document never
+// produces partial printer output.
+func decodeCommands(dec *xml.Decoder) ([]printCmd, error) {
+	var cmds []printCmd
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return cmds, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		builder, ok := elementBuilders[se.Name.Local]
+		if !ok {
+			if err := dec.Skip(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		cmd, err := builder(dec, se)
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, cmd)
+	}
+}
+
+// decodeMonoRaster turns a packed 1-bit-per-pixel raster (MSB first, rows
+// padded to a whole byte) into an image.Image suitable for Escpos.Image.
+func decodeMonoRaster(raw []byte, width, height int) (image.Image, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("epos-server: image element missing width/height")
+	}
+	widthBytes := (width + 7) / 8
+	if len(raw) < widthBytes*height {
+		return nil, fmt.Errorf("epos-server: image data too short for %dx%d", width, height)
+	}
+
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			bit := raw[y*widthBytes+x/8] & (0x80 >> uint(x%8))
+			v := color.Gray{Y: 255}
+			if bit != 0 {
+				v = color.Gray{Y: 0}
+			}
+			img.SetGray(x, y, v)
+		}
+	}
+	return img, nil
+}
+
+func barcodeFormat(typ string) int {
+	switch typ {
+	case "upc_a":
+		return 0
+	case "upc_e":
+		return 1
+	case "ean13", "jan13":
+		return 2
+	case "ean8", "jan8":
+		return 3
+	case "code39":
+		return 4
+	case "code128":
+		return 73
+	default:
+		return 0
+	}
+}
+
+// response is the ePOS-Print reply document.
+type response struct {
+	XMLName xml.Name `xml:"response"`
+	Success bool     `xml:"success,attr"`
+	Code    string   `xml:"code,attr"`
+	Status  int      `xml:"status,attr"`
+}
+
+// nextStartElement advances dec to, and returns, the next xml.StartElement
+// token.
+func nextStartElement(dec *xml.Decoder) (xml.StartElement, error) {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return se, nil
+		}
+	}
+}
+
+// serviceHandler implements POST /cgi-bin/epos/service.cgi?devid=...
+func (s *server) serviceHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	devID := r.URL.Query().Get("devid")
+	d, ok := s.device(devID)
+	if !ok {
+		writeResponse(w, response{Success: false, Code: "DEVICE_NOT_FOUND", Status: http.StatusNotFound})
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeResponse(w, response{Success: false, Code: "READ_ERROR", Status: http.StatusBadRequest})
+		return
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	root, err := nextStartElement(dec)
+	if err != nil || root.Name.Local != "epos-print" {
+		writeResponse(w, response{Success: false, Code: "SCHEMA_ERROR", Status: http.StatusBadRequest})
+		return
+	}
+
+	// Decode and validate the whole document before touching the printer, so
+	// a malformed element later in the document can't leave a partial
+	// receipt printed. Elements still execute in document order.
+	cmds, err := decodeCommands(dec)
+	if err != nil {
+		writeResponse(w, response{Success: false, Code: "SCHEMA_ERROR", Status: http.StatusBadRequest})
+		return
+	}
+
+	d.mu.Lock()
+	var dispatchErr error
+	for _, cmd := range cmds {
+		if err := cmd(d.e); err != nil {
+			dispatchErr = err
+			break
+		}
+	}
+	if dispatchErr == nil {
+		dispatchErr = d.e.Err()
+	}
+	d.mu.Unlock()
+
+	if dispatchErr != nil {
+		writeResponse(w, response{Success: false, Code: "PRINTER_ERROR", Status: http.StatusInternalServerError})
+		return
+	}
+	writeResponse(w, response{Success: true, Code: "", Status: http.StatusOK})
+}
+
+func writeResponse(w http.ResponseWriter, resp response) {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	if resp.Status != 0 {
+		w.WriteHeader(resp.Status)
+	}
+	fmt.Fprint(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	_ = enc.Encode(resp)
+}
+
+func main() {
+	addr := flag.String("addr", ":8080", "HTTP listen address")
+	devID := flag.String("devid", "local_printer", "devid this server answers requests for")
+	printerAddr := flag.String("printer", "", "TCP address of the ESC/POS printer, e.g. 192.168.1.50:9100")
+	flag.Parse()
+
+	if *printerAddr == "" {
+		log.Fatal("epos-server: -printer is required")
+	}
+
+	conn, err := net.Dial("tcp", *printerAddr)
+	if err != nil {
+		log.Fatalf("epos-server: dial printer: %v", err)
+	}
+	defer conn.Close()
+
+	s := newServer()
+	s.register(*devID, conn)
+
+	http.HandleFunc("/cgi-bin/epos/service.cgi", s.serviceHandler)
+
+	log.Printf("epos-server: listening on %s (devid=%s, printer=%s)", *addr, *devID, *printerAddr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
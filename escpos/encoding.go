@@ -0,0 +1,110 @@
+package escpos
+
+import (
+	"bytes"
+	"fmt"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/transform"
+)
+
+// CodePage identifies a printer character code page, sent with SetCodePage
+// via ESC t n.
+type CodePage byte
+
+const (
+	CP437  CodePage = 0  // USA: Standard Europe
+	CP850  CodePage = 2  // Multilingual
+	CP858  CodePage = 19 // Euro
+	CP1252 CodePage = 16 // Windows-1252 (Latin-1 superset)
+
+	// The multibyte CJK pages below are not part of Epson's standard ESC t
+	// table (0-19 and a handful of higher single-byte pages are reserved
+	// there; 1, 13, 14 and 15 are already taken by Katakana, PC857, PC737
+	// and ISO8859-7 respectively). They're placed in the vendor-extended
+	// range that Chinese-market ESC/POS clones commonly use for this
+	// purpose; confirm against the target printer's manual and remap with
+	// RegisterCodePage if it disagrees.
+	CP932 CodePage = 90 // Shift-JIS (Japanese)
+	CP936 CodePage = 91 // GBK / GB18030 (Simplified Chinese)
+	CP949 CodePage = 92 // EUC-KR (Korean)
+	CP950 CodePage = 93 // Big5 (Traditional Chinese)
+
+	// CPUTF8 passes text through unmodified, for printers (or terminal
+	// emulators) that already expect UTF-8.
+	CPUTF8 CodePage = 255
+)
+
+// Encoder converts a UTF-8 string to the byte sequence a printer's active
+// code page expects.
+type Encoder interface {
+	Encode(s string) ([]byte, error)
+}
+
+// xtextEncoder adapts a golang.org/x/text/encoding.Encoding to Encoder.
+type xtextEncoder struct {
+	enc encoding.Encoding
+}
+
+func (x xtextEncoder) Encode(s string) ([]byte, error) {
+	reader := transform.NewReader(bytes.NewReader([]byte(s)), x.enc.NewEncoder())
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(reader); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// utf8Encoder passes the string through unmodified.
+type utf8Encoder struct{}
+
+func (utf8Encoder) Encode(s string) ([]byte, error) {
+	return []byte(s), nil
+}
+
+// codePageEncoders holds the built-in CodePage -> Encoder registrations.
+var codePageEncoders = map[CodePage]Encoder{
+	CP437:  xtextEncoder{charmap.CodePage437},
+	CP850:  xtextEncoder{charmap.CodePage850},
+	CP858:  xtextEncoder{charmap.CodePage858},
+	CP1252: xtextEncoder{charmap.Windows1252},
+	CP932:  xtextEncoder{japanese.ShiftJIS},
+	CP936:  xtextEncoder{simplifiedchinese.GB18030},
+	CP949:  xtextEncoder{korean.EUCKR},
+	CP950:  xtextEncoder{traditionalchinese.Big5},
+	CPUTF8: utf8Encoder{},
+}
+
+// RegisterCodePage associates id with enc, so it can be selected with
+// SetCodePage(CodePage(id)). This lets callers plug in any
+// golang.org/x/text/encoding encoder not already built in.
+func RegisterCodePage(id byte, enc encoding.Encoding) {
+	codePageEncoders[CodePage(id)] = xtextEncoder{enc}
+}
+
+// WithCodePage sets the initial code page used by a newly constructed
+// Escpos, in place of the default (CP936, for backwards compatibility with
+// this package's historical GB18030-only behaviour).
+func WithCodePage(page CodePage) Option {
+	return func(e *Escpos) {
+		e.SetCodePage(page)
+	}
+}
+
+// SetCodePage selects page as the active code page: it emits ESC t n and
+// switches the encoder used by subsequent Write calls.
+func (e *Escpos) SetCodePage(page CodePage) *Escpos {
+	enc, ok := codePageEncoders[page]
+	if !ok {
+		e.setErr(fmt.Errorf("escpos: unknown code page %d", page))
+		return e
+	}
+	e.codePage = page
+	e.encoder = enc
+	return e.WriteRaw([]byte{0x1B, 't', byte(page)})
+}
@@ -1,14 +1,10 @@
 package escpos
 
 import (
-	"bytes"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"strings"
-
-	"golang.org/x/text/encoding/simplifiedchinese"
-	"golang.org/x/text/transform"
+	"sync"
 )
 
 const (
@@ -22,6 +18,33 @@ const (
 	GS byte = 0x1D
 )
 
+// Align selects text justification, used with the Align method.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// Font selects the character font, used with the Font method.
+type Font int
+
+const (
+	FontA Font = iota
+	FontB
+	FontC
+)
+
+// Underline selects the underline weight, used with the Underline method.
+type Underline uint8
+
+const (
+	UnderlineOff  Underline = 0
+	Underline1Dot Underline = 1
+	Underline2Dot Underline = 2
+)
+
 // text replacement map
 var textReplaceMap = map[string]string{
 	// horizontal tab
@@ -50,14 +73,28 @@ func textReplace(data string) string {
 	return data
 }
 
-func (e *Escpos) SetChineseOn() {
-	e.Write(fmt.Sprintf("\x1C&"))
+func (e *Escpos) SetChineseOn() *Escpos {
+	return e.Write(fmt.Sprintf("\x1C&"))
 }
 
 type Escpos struct {
 	// destination
 	dst io.ReadWriter
 
+	// first error encountered by a mutator/writer since the last Err() read
+	err error
+
+	// active code page and the encoder used to transcode Write's argument
+	// to it
+	codePage CodePage
+	encoder  Encoder
+
+	// serializes DLE EOT responses against unsolicited ASB frames; created
+	// lazily by the first EnableASB(true) or StatusMonitor.Start call
+	pumpOnce   sync.Once
+	statusPump *statusPump
+	asbEnabled bool
+
 	// font metrics
 	width, height uint8
 
@@ -85,20 +122,46 @@ func (e *Escpos) reset() {
 	e.smooth = 0
 }
 
+// Option configures an Escpos instance at construction time.
+type Option func(*Escpos)
+
 // create Escpos printer
-func New(dst io.ReadWriter) (e *Escpos) {
+func New(dst io.ReadWriter, opts ...Option) (e *Escpos) {
 	e = &Escpos{dst: dst}
 	e.reset()
+	// CP936 (GB18030) matches this package's historical behaviour; pass
+	// WithCodePage to target a different locale.
+	e.codePage = CP936
+	e.encoder = codePageEncoders[CP936]
+	for _, opt := range opts {
+		opt(e)
+	}
 	return
 }
 
+// Err returns the first error encountered by a chained call since the last
+// time Err was checked, if any, and clears it so the next call starts fresh.
+func (e *Escpos) Err() error {
+	err := e.err
+	e.err = nil
+	return err
+}
+
+// setErr records err as e's error if one hasn't already been recorded.
+func (e *Escpos) setErr(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
+
 // write raw bytes to printer
-func (e *Escpos) WriteRaw(data []byte) (n int, err error) {
+func (e *Escpos) WriteRaw(data []byte) *Escpos {
 	if len(data) > 0 {
-		e.dst.Write(data)
+		if _, err := e.dst.Write(data); err != nil {
+			e.setErr(err)
+		}
 	}
-
-	return 0, nil
+	return e
 }
 
 // read raw bytes from printer
@@ -106,205 +169,253 @@ func (e *Escpos) ReadRaw(data []byte) (n int, err error) {
 	return e.dst.Read(data)
 }
 
-// write a string to the printer
-func (e *Escpos) Write(data string) (int, error) {
-	reader := transform.NewReader(bytes.NewReader([]byte(data)), simplifiedchinese.GB18030.NewEncoder())
-	bs, _ := ioutil.ReadAll(reader)
+// write a string to the printer, transcoding it to the active code page
+func (e *Escpos) Write(data string) *Escpos {
+	bs, err := e.encoder.Encode(data)
+	if err != nil {
+		e.setErr(err)
+		return e
+	}
 	return e.WriteRaw(bs)
 }
 
 //开钱箱
-func (e *Escpos) OpenDrawer() {
-	e.WriteRaw([]byte{0x1b, 0x70, byte(0), byte(10), byte(10)})
+func (e *Escpos) OpenDrawer() *Escpos {
+	return e.WriteRaw([]byte{0x1b, 0x70, byte(0), byte(10), byte(10)})
 }
 
 // init/reset printer settings
-func (e *Escpos) Init() {
+func (e *Escpos) Init() *Escpos {
 	e.reset()
-	e.Write("\x1B@")
+	return e.Write("\x1B@")
 }
 
 // end output
-func (e *Escpos) End() {
-	e.Write("\xFA")
+func (e *Escpos) End() *Escpos {
+	return e.Write("\xFA")
 }
 
 // send cut
-func (e *Escpos) Cut() {
-	e.Write("\x1DVA0")
+func (e *Escpos) Cut() *Escpos {
+	return e.Write("\x1DVA0")
 }
 
 // send cut minus one point (partial cut)
-func (e *Escpos) CutPartial() {
-	e.WriteRaw([]byte{GS, 0x56, 1})
+func (e *Escpos) CutPartial() *Escpos {
+	return e.WriteRaw([]byte{GS, 0x56, 1})
 }
 
 // send cash
-func (e *Escpos) Cash() {
-	e.Write("\x1B\x70\x00\x0A\xFF")
+func (e *Escpos) Cash() *Escpos {
+	return e.Write("\x1B\x70\x00\x0A\xFF")
 }
 
 // send linefeed
-func (e *Escpos) Linefeed() {
-	e.Write("\n")
+func (e *Escpos) Linefeed() *Escpos {
+	return e.Write("\n")
 }
 
 // send N formfeeds
-func (e *Escpos) FormfeedN(n int) {
-	e.Write(fmt.Sprintf("\x1Bd%c", n))
+func (e *Escpos) FormfeedN(n int) *Escpos {
+	return e.Write(fmt.Sprintf("\x1Bd%c", n))
 }
 
 // send formfeed
-func (e *Escpos) Formfeed() {
-	e.FormfeedN(1)
+func (e *Escpos) Formfeed() *Escpos {
+	return e.FormfeedN(1)
 }
 
-// set font
-func (e *Escpos) SetFont(font string) {
-	f := 0
+// Font sets the character font.
+func (e *Escpos) Font(f Font) *Escpos {
+	return e.Write(fmt.Sprintf("\x1BM%c", f))
+}
+
+// SetFont sets the character font by name ("A", "B" or "C").
+//
+// Deprecated: use Font with the FontA/FontB/FontC constants instead.
+func (e *Escpos) SetFont(font string) *Escpos {
+	f := FontA
 
 	switch font {
 	case "A":
-		f = 0
+		f = FontA
 	case "B":
-		f = 1
+		f = FontB
 	case "C":
-		f = 2
-	default:
-		f = 0
+		f = FontC
 	}
 
-	e.Write(fmt.Sprintf("\x1BM%c", f))
+	return e.Font(f)
 }
 
-func (e *Escpos) SendFontSize() {
-	e.Write(fmt.Sprintf("\x1D!%c", ((e.width)<<4)|(e.height)))
+func (e *Escpos) SendFontSize() *Escpos {
+	return e.Write(fmt.Sprintf("\x1D!%c", ((e.width)<<4)|(e.height)))
 }
-func (e *Escpos) SetFontStyle(style uint8) {
-	e.Write(string([]byte{0x1b, 0x21, byte(style)}))
+
+func (e *Escpos) SetFontStyle(style uint8) *Escpos {
+	return e.Write(string([]byte{0x1b, 0x21, byte(style)}))
 }
 
-func (e *Escpos) SetLetterSpace(n int) {
-	e.Write(string([]byte{0x1b, 0x20, byte(n)}))
+func (e *Escpos) SetLetterSpace(n int) *Escpos {
+	return e.Write(string([]byte{0x1b, 0x20, byte(n)}))
 }
 
-// set font size
-func (e *Escpos) SetFontSize(width, height uint8) {
+// Size sets the font width and height scale, each 0-7 and sent as the GS !
+// nibble (0 is normal size, 7 is eight times); values of 8 or more are
+// ignored.
+func (e *Escpos) Size(width, height uint8) *Escpos {
 	if width >= 0 && height >= 0 && width < 8 && height < 8 {
 		e.width = width
 		e.height = height
-		e.SendFontSize()
+		return e.SendFontSize()
 	}
+	return e
+}
+
+// SetFontSize sets the font width and height scale (0-7), as Size does.
+//
+// Deprecated: use Size instead.
+func (e *Escpos) SetFontSize(width, height uint8) *Escpos {
+	return e.Size(width, height)
 }
 
-func (e *Escpos) SetFontColor(color uint8) {
-	e.WriteRaw([]byte{0x1b, 0x72, byte(color)})
+func (e *Escpos) SetFontColor(color uint8) *Escpos {
+	return e.WriteRaw([]byte{0x1b, 0x72, byte(color)})
 }
 
 // send underline
-func (e *Escpos) SendUnderline() {
-	e.Write(fmt.Sprintf("\x1B-%c", e.underline))
+func (e *Escpos) SendUnderline() *Escpos {
+	return e.Write(fmt.Sprintf("\x1B-%c", e.underline))
 }
 
 // send emphasize / doublestrike
-func (e *Escpos) SendEmphasize() {
-	e.Write(fmt.Sprintf("\x1BG%c", e.emphasize))
+func (e *Escpos) SendEmphasize() *Escpos {
+	return e.Write(fmt.Sprintf("\x1BG%c", e.emphasize))
 }
 
 // send upsidedown
-func (e *Escpos) SendUpsidedown() {
-	e.Write(fmt.Sprintf("\x1B{%c", e.upsidedown))
+func (e *Escpos) SendUpsidedown() *Escpos {
+	return e.Write(fmt.Sprintf("\x1B{%c", e.upsidedown))
 }
 
 // send rotate
-func (e *Escpos) SendRotate() {
-	e.Write(fmt.Sprintf("\x1BR%c", e.rotate))
+func (e *Escpos) SendRotate() *Escpos {
+	return e.Write(fmt.Sprintf("\x1BR%c", e.rotate))
 }
 
 // send reverse
-func (e *Escpos) SendReverse() {
-	e.Write(fmt.Sprintf("\x1DB%c", e.reverse))
+func (e *Escpos) SendReverse() *Escpos {
+	return e.Write(fmt.Sprintf("\x1DB%c", e.reverse))
 }
 
 // send smooth
-func (e *Escpos) SendSmooth() {
-	e.Write(fmt.Sprintf("\x1Db%c", e.smooth))
+func (e *Escpos) SendSmooth() *Escpos {
+	return e.Write(fmt.Sprintf("\x1Db%c", e.smooth))
 }
 
 // 光标移动到x位置
-func (e *Escpos) SendMoveX(x int) {
-	e.Write(string([]byte{0x1b, 0x24, byte(x % 256), byte(x / 256)}))
+func (e *Escpos) SendMoveX(x int) *Escpos {
+	return e.Write(string([]byte{0x1b, 0x24, byte(x % 256), byte(x / 256)}))
 }
 
 // send move y
-func (e *Escpos) SendMoveY(y int) {
-	e.Write(string([]byte{0x1d, 0x24, byte(y % 256), byte(y / 256)}))
+func (e *Escpos) SendMoveY(y int) *Escpos {
+	return e.Write(string([]byte{0x1d, 0x24, byte(y % 256), byte(y / 256)}))
 }
 
-// set underline
-func (e *Escpos) SetUnderline(v uint8) {
-	e.underline = v
-	e.SendUnderline()
+// Underline sets the underline weight.
+func (e *Escpos) Underline(u Underline) *Escpos {
+	e.underline = uint8(u)
+	return e.SendUnderline()
 }
 
-// set emphasize
-func (e *Escpos) SetEmphasize(u uint8) {
+// SetUnderline sets the underline weight.
+//
+// Deprecated: use Underline with the Underline1Dot/Underline2Dot constants
+// instead.
+func (e *Escpos) SetUnderline(v uint8) *Escpos {
+	return e.Underline(Underline(v))
+}
+
+// Bold toggles emphasized (bold) printing.
+func (e *Escpos) Bold(on bool) *Escpos {
+	if on {
+		e.emphasize = 1
+	} else {
+		e.emphasize = 0
+	}
+	return e.SendEmphasize()
+}
+
+// SetEmphasize sets emphasize / doublestrike.
+//
+// Deprecated: use Bold instead.
+func (e *Escpos) SetEmphasize(u uint8) *Escpos {
 	e.emphasize = u
-	e.SendEmphasize()
+	return e.SendEmphasize()
 }
 
 // set upsidedown
-func (e *Escpos) SetUpsidedown(v uint8) {
+func (e *Escpos) SetUpsidedown(v uint8) *Escpos {
 	e.upsidedown = v
-	e.SendUpsidedown()
+	return e.SendUpsidedown()
 }
 
 // set rotate
-func (e *Escpos) SetRotate(v uint8) {
+func (e *Escpos) SetRotate(v uint8) *Escpos {
 	e.rotate = v
-	e.SendRotate()
+	return e.SendRotate()
 }
 
 // set reverse
-func (e *Escpos) SetReverse(v uint8) {
+func (e *Escpos) SetReverse(v uint8) *Escpos {
 	e.reverse = v
-	e.SendReverse()
+	return e.SendReverse()
 }
 
 // set smooth
-func (e *Escpos) SetSmooth(v uint8) {
+func (e *Escpos) SetSmooth(v uint8) *Escpos {
 	e.smooth = v
-	e.SendSmooth()
+	return e.SendSmooth()
 }
 
 // pulse (open the drawer)
-func (e *Escpos) Pulse() {
+func (e *Escpos) Pulse() *Escpos {
 	// with t=2 -- meaning 2*2msec
-	e.Write("\x1Bp\x02")
+	return e.Write("\x1Bp\x02")
 }
 
-// set alignment
-func (e *Escpos) SetAlign(align string) {
-	a := 0
+// Align sets text justification.
+func (e *Escpos) Align(a Align) *Escpos {
+	return e.Write(fmt.Sprintf("\x1Ba%c", a))
+}
+
+// SetAlign sets text justification by name ("left", "center" or "right").
+//
+// Deprecated: use Align with the AlignLeft/AlignCenter/AlignRight constants
+// instead.
+func (e *Escpos) SetAlign(align string) *Escpos {
+	a := AlignLeft
 	switch align {
 	case "left":
-		a = 0
+		a = AlignLeft
 	case "center":
-		a = 1
+		a = AlignCenter
 	case "right":
-		a = 2
+		a = AlignRight
 	}
-	e.Write(fmt.Sprintf("\x1Ba%c", a))
+	return e.Align(a)
 }
 
-func (e *Escpos) SetMarginLeft(size uint16) {
+func (e *Escpos) SetMarginLeft(size uint16) *Escpos {
 	if size <= 47 {
-		e.Write(string([]byte{0x1d, 0x4c, byte(size % 256), byte(size / 256)}))
+		return e.Write(string([]byte{0x1d, 0x4c, byte(size % 256), byte(size / 256)}))
 	}
+	return e
 }
 
 // set language -- ESC R
-func (e *Escpos) SetLang(lang string) {
+func (e *Escpos) SetLang(lang string) *Escpos {
 	l := 0
 
 	switch lang {
@@ -329,20 +440,20 @@ func (e *Escpos) SetLang(lang string) {
 	case "no":
 		l = 9
 	}
-	e.Write(fmt.Sprintf("\x1BR%c", l))
+	return e.Write(fmt.Sprintf("\x1BR%c", l))
 }
 
 // feed and cut based on parameters
-func (e *Escpos) FeedAndCut(params map[string]string) {
+func (e *Escpos) FeedAndCut(params map[string]string) *Escpos {
 	if t, ok := params["type"]; ok && t == "feed" {
 		e.Formfeed()
 	}
 
-	e.Cut()
+	return e.Cut()
 }
 
 // Barcode sends a barcode to the printer.
-func (e *Escpos) Barcode(barcode string, format int) {
+func (e *Escpos) Barcode(barcode string, format int) *Escpos {
 	code := ""
 	switch format {
 	case 0:
@@ -363,7 +474,7 @@ func (e *Escpos) Barcode(barcode string, format int) {
 	e.reset()
 
 	// set align
-	e.SetAlign("center")
+	e.Align(AlignCenter)
 
 	// write barcode
 	if format > 69 {
@@ -371,7 +482,7 @@ func (e *Escpos) Barcode(barcode string, format int) {
 	} else if format < 69 {
 		e.Write(fmt.Sprintf("\x1dk"+code+"%v\x00", barcode))
 	}
-	e.Write(fmt.Sprintf("%v", barcode))
+	return e.Write(fmt.Sprintf("%v", barcode))
 }
 
 // used to send graphics headers
@@ -383,13 +494,4 @@ func (e *Escpos) gSend(m byte, fn byte, data []byte) {
 	e.WriteRaw(data)
 }
 
-// ReadStatus Read the status n from the printer
-func (e *Escpos) ReadStatus(n byte) (byte, error) {
-	e.WriteRaw([]byte{DLE, EOT, n})
-	data := make([]byte, 1)
-	_, err := e.ReadRaw(data)
-	if err != nil {
-		return 0, err
-	}
-	return data[0], nil
-}
+// ReadStatus lives in status.go, alongside EnableASB and StatusMonitor.
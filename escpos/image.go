@@ -0,0 +1,253 @@
+package escpos
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// DitherAlgorithm selects how a greyscale image is reduced to 1-bit-per-pixel
+// before printing.
+type DitherAlgorithm int
+
+const (
+	// DitherFloydSteinberg diffuses quantization error to neighbouring
+	// pixels and gives the best looking result for photos.
+	DitherFloydSteinberg DitherAlgorithm = iota
+	// DitherOrdered applies a fixed 4x4 Bayer threshold matrix; it is
+	// cheaper and tiles well for logos and line art.
+	DitherOrdered
+	// DitherThreshold does a plain 50% threshold with no dithering.
+	DitherThreshold
+)
+
+// rasterMethod selects which raster command family Image uses to send the
+// bitmap to the printer.
+type rasterMethod int
+
+const (
+	// RasterBitImage uses GS v 0, supported by virtually every ESC/POS
+	// printer.
+	RasterBitImage rasterMethod = iota
+	// RasterGraphics uses the GS ( L graphics command (function 112/50),
+	// required by some printers instead of GS v 0.
+	RasterGraphics
+)
+
+// ImageOptions controls how Image converts and prints a bitmap.
+type ImageOptions struct {
+	// Dither selects the halftoning algorithm used to reduce the image to
+	// 1-bit-per-pixel. Defaults to DitherFloydSteinberg.
+	Dither DitherAlgorithm
+
+	// MaxWidth clamps the printed width in dots. If zero, DefaultPaperWidth
+	// is used.
+	MaxWidth uint16
+
+	// Method selects the raster command family. Defaults to RasterBitImage.
+	Method rasterMethod
+}
+
+// DefaultPaperWidth is the printable width, in dots, assumed when
+// ImageOptions.MaxWidth is zero.
+const DefaultPaperWidth = 384
+
+// maxRasterLines is the largest number of raster lines accepted by a single
+// GS v 0 / GS ( L command; taller images are split into successive commands.
+const maxRasterLines = 1662
+
+// Image converts img to a monochrome bitmap and prints it, scaling it down
+// (preserving aspect ratio) to fit within the paper width if necessary.
+func (e *Escpos) Image(img image.Image, opts ImageOptions) error {
+	maxWidth := int(opts.MaxWidth)
+	if maxWidth == 0 {
+		maxWidth = DefaultPaperWidth
+	}
+
+	bounds := img.Bounds()
+	width := bounds.Dx()
+	height := bounds.Dy()
+	if width == 0 || height == 0 {
+		return fmt.Errorf("escpos: image has zero dimensions")
+	}
+
+	if width > maxWidth {
+		img = resizeNearest(img, maxWidth)
+		bounds = img.Bounds()
+		width = bounds.Dx()
+		height = bounds.Dy()
+	}
+
+	bitmap := ditherImage(img, opts.Dither)
+
+	widthBytes := (width + 7) / 8
+	rowBytes := make([][]byte, height)
+	for y := 0; y < height; y++ {
+		row := make([]byte, widthBytes)
+		for x := 0; x < width; x++ {
+			if bitmap[y*width+x] {
+				row[x/8] |= 0x80 >> uint(x%8)
+			}
+		}
+		rowBytes[y] = row
+	}
+
+	// a GS ( L command's two-byte length covers 10 header bytes plus the
+	// raster data, so graphics chunks are also capped to fit in 65535 bytes
+	lines := maxRasterLines
+	if opts.Method == RasterGraphics && (65535-10)/widthBytes < lines {
+		lines = (65535 - 10) / widthBytes
+	}
+	for start := 0; start < height; start += lines {
+		end := start + lines
+		if end > height {
+			end = height
+		}
+		chunk := rowBytes[start:end]
+		switch opts.Method {
+		case RasterGraphics:
+			e.printRasterGraphics(width, widthBytes, chunk)
+		default:
+			e.printRasterBitImage(widthBytes, chunk)
+		}
+	}
+
+	return nil
+}
+
+// printRasterBitImage emits GS v 0 m xL xH yL yH d1...dk.
+func (e *Escpos) printRasterBitImage(widthBytes int, rows [][]byte) {
+	yL := byte(len(rows) % 256)
+	yH := byte(len(rows) / 256)
+	xL := byte(widthBytes % 256)
+	xH := byte(widthBytes / 256)
+
+	e.WriteRaw([]byte{GS, 'v', '0', 0, xL, xH, yL, yH})
+	for _, row := range rows {
+		e.WriteRaw(row)
+	}
+}
+
+// gsSend emits a GS ( L function, the raster counterpart to gSend's ESC ( L.
+func (e *Escpos) gsSend(m byte, fn byte, data []byte) {
+	l := len(data) + 2
+	e.WriteRaw([]byte{GS, '(', 'L', byte(l % 256), byte(l / 256), m, fn})
+	e.WriteRaw(data)
+}
+
+// printRasterGraphics emits the image via GS ( L function 112 (store raster
+// data) followed by function 50 (print the stored data), for printers that
+// don't support GS v 0. gsSend already writes the pL pH m fn header, so data
+// starts directly at the function-112 parameters: a bx by c xL xH yL yH,
+// where xL/xH are a dot count (not a byte count) and yL/yH are a line count.
+func (e *Escpos) printRasterGraphics(widthDots, widthBytes int, rows [][]byte) {
+	data := make([]byte, 0, 8+widthBytes*len(rows))
+	data = append(data, 48, 1, 1, 49,
+		byte(widthDots%256), byte(widthDots/256),
+		byte(len(rows)%256), byte(len(rows)/256))
+	for _, row := range rows {
+		data = append(data, row...)
+	}
+	e.gsSend(0x30, 0x70, data)
+	e.gsSend(0x30, 0x32, nil)
+}
+
+// resizeNearest scales img down to targetWidth dots, preserving aspect
+// ratio, using nearest-neighbour sampling.
+func resizeNearest(img image.Image, targetWidth int) image.Image {
+	bounds := img.Bounds()
+	srcWidth := bounds.Dx()
+	srcHeight := bounds.Dy()
+
+	targetHeight := srcHeight * targetWidth / srcWidth
+	if targetHeight < 1 {
+		targetHeight = 1
+	}
+
+	dst := image.NewGray(image.Rect(0, 0, targetWidth, targetHeight))
+	for y := 0; y < targetHeight; y++ {
+		srcY := bounds.Min.Y + y*srcHeight/targetHeight
+		for x := 0; x < targetWidth; x++ {
+			srcX := bounds.Min.X + x*srcWidth/targetWidth
+			dst.Set(x, y, img.At(srcX, srcY))
+		}
+	}
+	return dst
+}
+
+// ditherImage reduces img to a row-major slice of booleans (true = printed
+// dot) using the requested algorithm.
+func ditherImage(img image.Image, algo DitherAlgorithm) []bool {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	gray := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray)
+			gray[y*width+x] = float64(c.Y)
+		}
+	}
+
+	out := make([]bool, width*height)
+	switch algo {
+	case DitherOrdered:
+		ditherOrdered(gray, out, width, height)
+	case DitherThreshold:
+		for i, v := range gray {
+			out[i] = v < 128
+		}
+	default:
+		ditherFloydSteinberg(gray, out, width, height)
+	}
+	return out
+}
+
+func ditherFloydSteinberg(gray []float64, out []bool, width, height int) {
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			old := gray[i]
+			black := old < 128
+			out[i] = black
+			var newVal float64
+			if black {
+				newVal = 0
+			} else {
+				newVal = 255
+			}
+			errVal := old - newVal
+
+			if x+1 < width {
+				gray[i+1] += errVal * 7 / 16
+			}
+			if y+1 < height {
+				if x > 0 {
+					gray[i+width-1] += errVal * 3 / 16
+				}
+				gray[i+width] += errVal * 5 / 16
+				if x+1 < width {
+					gray[i+width+1] += errVal * 1 / 16
+				}
+			}
+		}
+	}
+}
+
+// bayer4x4 is the standard 4x4 ordered-dithering index matrix, scaled by 16
+// to [0, 240]. ditherOrdered adds 8 to each entry, giving thresholds in
+// [8, 248], so every cell admits both black and white pixels.
+var bayer4x4 = [4][4]float64{
+	{0 * 16, 8 * 16, 2 * 16, 10 * 16},
+	{12 * 16, 4 * 16, 14 * 16, 6 * 16},
+	{3 * 16, 11 * 16, 1 * 16, 9 * 16},
+	{15 * 16, 7 * 16, 13 * 16, 5 * 16},
+}
+
+func ditherOrdered(gray []float64, out []bool, width, height int) {
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			threshold := bayer4x4[y%4][x%4] + 8
+			out[y*width+x] = gray[y*width+x] < threshold
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package escpos
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status query selectors for DLE EOT n.
+const (
+	statusPrinter byte = 1
+	statusOffline byte = 2
+	statusError   byte = 3
+	statusPaper   byte = 4
+)
+
+// PrinterStatus decodes the DLE EOT 1 (printer status) response.
+type PrinterStatus struct {
+	DrawerKickedOut bool
+	Offline         bool
+}
+
+// OfflineStatus decodes the DLE EOT 2 (offline cause) response. This is
+// where the cover-open and paper-feed-button signals actually live.
+type OfflineStatus struct {
+	CoverOpen       bool
+	PaperFeedButton bool
+}
+
+// PaperStatus decodes the DLE EOT 4 (paper roll sensor) response.
+type PaperStatus struct {
+	NearEnd bool
+	Empty   bool
+}
+
+// ErrorStatus decodes the DLE EOT 3 (error cause) response.
+type ErrorStatus struct {
+	Cutter          bool
+	Unrecoverable   bool
+	AutoRecoverable bool
+}
+
+// StatusKind identifies which field of a StatusEvent is populated.
+type StatusKind int
+
+const (
+	StatusKindPrinter StatusKind = iota
+	StatusKindOffline
+	StatusKindError
+	StatusKindPaper
+	StatusKindASB
+)
+
+// StatusEvent is delivered on a StatusMonitor's channel whenever a status
+// query or an unsolicited ASB frame is decoded.
+type StatusEvent struct {
+	Kind    StatusKind
+	Raw     byte
+	Printer *PrinterStatus
+	Offline *OfflineStatus
+	Paper   *PaperStatus
+	Error   *ErrorStatus
+}
+
+func decodePrinterStatus(b byte) PrinterStatus {
+	return PrinterStatus{
+		DrawerKickedOut: b&0x04 != 0,
+		Offline:         b&0x08 != 0,
+	}
+}
+
+func decodeOfflineStatus(b byte) OfflineStatus {
+	return OfflineStatus{
+		CoverOpen:       b&0x04 != 0,
+		PaperFeedButton: b&0x08 != 0,
+	}
+}
+
+func decodePaperStatus(b byte) PaperStatus {
+	return PaperStatus{
+		NearEnd: b&0x0C != 0,
+		Empty:   b&0x60 != 0,
+	}
+}
+
+func decodeErrorStatus(b byte) ErrorStatus {
+	return ErrorStatus{
+		Cutter:          b&0x08 != 0,
+		Unrecoverable:   b&0x20 != 0,
+		AutoRecoverable: b&0x40 != 0,
+	}
+}
+
+// statusPump serializes access to dst so DLE EOT responses (requested by
+// ReadStatus) and unsolicited ASB frames (requested by EnableASB) don't get
+// interleaved on the wire.
+type statusPump struct {
+	e        *Escpos
+	pending  int32
+	respChan chan byte
+	asbChan  chan [4]byte
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+func (e *Escpos) pump() *statusPump {
+	e.pumpOnce.Do(func() {
+		p := &statusPump{
+			e:        e,
+			respChan: make(chan byte),
+			asbChan:  make(chan [4]byte, 16),
+			stopCh:   make(chan struct{}),
+		}
+		e.statusPump = p
+		go p.run()
+	})
+	return e.statusPump
+}
+
+func (p *statusPump) run() {
+	buf := make([]byte, 1)
+	var asbBuf [4]byte
+	asbLen := 0
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		default:
+		}
+
+		n, err := p.e.ReadRaw(buf)
+		if err != nil {
+			return
+		}
+		if n == 0 {
+			continue
+		}
+
+		if atomic.LoadInt32(&p.pending) > 0 {
+			select {
+			case p.respChan <- buf[0]:
+			case <-p.stopCh:
+				return
+			}
+			continue
+		}
+
+		asbBuf[asbLen] = buf[0]
+		asbLen++
+		if asbLen == 4 {
+			select {
+			case p.asbChan <- asbBuf:
+			default: // drop the frame rather than block the pump
+			}
+			asbLen = 0
+		}
+	}
+}
+
+func (p *statusPump) stop() {
+	p.stopOnce.Do(func() { close(p.stopCh) })
+}
+
+// statusReadTimeout bounds how long ReadStatus waits for a response once the
+// pump is in use (EnableASB or a StatusMonitor is active).
+const statusReadTimeout = 2 * time.Second
+
+// ReadStatus reads the status n from the printer (DLE EOT n).
+func (e *Escpos) ReadStatus(n byte) (byte, error) {
+	if e.statusPump == nil && !e.asbEnabled {
+		e.WriteRaw([]byte{DLE, EOT, n})
+		data := make([]byte, 1)
+		if _, err := e.ReadRaw(data); err != nil {
+			return 0, err
+		}
+		return data[0], nil
+	}
+
+	p := e.pump()
+	atomic.AddInt32(&p.pending, 1)
+	defer atomic.AddInt32(&p.pending, -1)
+
+	e.WriteRaw([]byte{DLE, EOT, n})
+	if err := e.Err(); err != nil {
+		return 0, err
+	}
+
+	select {
+	case b := <-p.respChan:
+		return b, nil
+	case <-time.After(statusReadTimeout):
+		return 0, fmt.Errorf("escpos: status read timed out")
+	}
+}
+
+// EnableASB turns Automatic Status Back on or off (GS a n). While enabled,
+// the printer pushes unsolicited 4-byte status frames whenever its state
+// changes; a StatusMonitor is required to receive them as StatusEvents.
+func (e *Escpos) EnableASB(on bool) *Escpos {
+	var v byte
+	if on {
+		v = 0x3F
+		e.pump()
+	}
+	e.asbEnabled = on
+	return e.WriteRaw([]byte{GS, 'a', v})
+}
+
+// StatusMonitor periodically polls printer status and decodes unsolicited
+// ASB frames, delivering both as StatusEvents.
+type StatusMonitor struct {
+	e        *Escpos
+	interval time.Duration
+	events   chan StatusEvent
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewStatusMonitor creates a StatusMonitor that polls e every interval once
+// started. Call Start to begin polling and Events to receive updates.
+func (e *Escpos) NewStatusMonitor(interval time.Duration) *StatusMonitor {
+	return &StatusMonitor{
+		e:        e,
+		interval: interval,
+		events:   make(chan StatusEvent, 16),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Events returns the channel StatusEvents are delivered on.
+func (m *StatusMonitor) Events() <-chan StatusEvent {
+	return m.events
+}
+
+// Start begins polling and, if ASB is enabled, listening for unsolicited
+// status frames.
+func (m *StatusMonitor) Start() {
+	p := m.e.pump()
+	go m.pollLoop()
+	go m.asbLoop(p)
+}
+
+// Stop halts the monitor. It does not disable ASB or close the events
+// channel, since other monitors or ReadStatus calls may still be using the
+// same underlying pump.
+func (m *StatusMonitor) Stop() {
+	m.stopOnce.Do(func() { close(m.stopCh) })
+}
+
+func (m *StatusMonitor) pollLoop() {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			for _, n := range []byte{statusPrinter, statusOffline, statusError, statusPaper} {
+				b, err := m.e.ReadStatus(n)
+				if err != nil {
+					continue
+				}
+				m.dispatch(n, b)
+			}
+		}
+	}
+}
+
+func (m *StatusMonitor) asbLoop(p *statusPump) {
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case frame := <-p.asbChan:
+			// an ASB frame packs the same four responses DLE EOT 1-4 would
+			// return: byte 0 printer status, byte 1 offline cause (cover,
+			// feed button), byte 2 error cause, byte 3 paper sensor.
+			ps := decodePrinterStatus(frame[0])
+			off := decodeOfflineStatus(frame[1])
+			es := decodeErrorStatus(frame[2])
+			paper := decodePaperStatus(frame[3])
+			m.send(StatusEvent{Kind: StatusKindASB, Raw: frame[0], Printer: &ps, Offline: &off, Error: &es, Paper: &paper})
+		}
+	}
+}
+
+func (m *StatusMonitor) dispatch(n, b byte) {
+	switch n {
+	case statusPrinter:
+		ps := decodePrinterStatus(b)
+		m.send(StatusEvent{Kind: StatusKindPrinter, Raw: b, Printer: &ps})
+	case statusOffline:
+		off := decodeOfflineStatus(b)
+		m.send(StatusEvent{Kind: StatusKindOffline, Raw: b, Offline: &off})
+	case statusError:
+		es := decodeErrorStatus(b)
+		m.send(StatusEvent{Kind: StatusKindError, Raw: b, Error: &es})
+	case statusPaper:
+		pa := decodePaperStatus(b)
+		m.send(StatusEvent{Kind: StatusKindPaper, Raw: b, Paper: &pa})
+	}
+}
+
+func (m *StatusMonitor) send(ev StatusEvent) {
+	select {
+	case m.events <- ev:
+	default: // drop if the consumer isn't keeping up
+	}
+}
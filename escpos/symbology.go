@@ -0,0 +1,104 @@
+package escpos
+
+import "fmt"
+
+// QRErrorCorrection selects the error-correction level used when a QR code
+// is printed. The values match the "n" parameter of GS ( k fn=69.
+type QRErrorCorrection uint8
+
+const (
+	QRErrorCorrectionL QRErrorCorrection = 48 + iota // ~7%
+	QRErrorCorrectionM                               // ~15%
+	QRErrorCorrectionQ                               // ~25%
+	QRErrorCorrectionH                               // ~30%
+)
+
+// cn values for the GS ( k (function 165) 2D symbology family.
+const (
+	cnPDF417     byte = 48
+	cnQRCode     byte = 49
+	cnDataMatrix byte = 54
+)
+
+// fn values shared across the GS ( k symbologies.
+const (
+	fnStoreData byte = 80
+	fnPrintData byte = 81
+)
+
+// gsFn165 sends a GS ( k function-165 command: GS ( k pL pH cn fn [data].
+func (e *Escpos) gsFn165(cn, fn byte, data []byte) *Escpos {
+	l := len(data) + 2
+	e.WriteRaw([]byte{GS, '(', 'k', byte(l % 256), byte(l / 256), cn, fn})
+	return e.WriteRaw(data)
+}
+
+// storeSymbolData stores data in the symbol storage area for cn. Each store
+// command replaces the symbol buffer rather than appending to it, so the
+// whole payload is sent as a single command; the pL/pH length prefix on
+// GS ( k already addresses payloads up to 65533 bytes.
+func (e *Escpos) storeSymbolData(cn byte, data []byte) *Escpos {
+	// the first byte of the parameter data (m=48) is fixed by the spec
+	chunk := make([]byte, 0, len(data)+1)
+	chunk = append(chunk, 0x30)
+	chunk = append(chunk, data...)
+	return e.gsFn165(cn, fnStoreData, chunk)
+}
+
+// QRCode prints a QR code for data using the given model (1 or 2) and
+// moduleSize (dots per module, 1-16). Any validation error is recorded on e
+// and can be retrieved with Err.
+func (e *Escpos) QRCode(data string, model int, moduleSize uint8, ec QRErrorCorrection) *Escpos {
+	if moduleSize < 1 || moduleSize > 16 {
+		e.setErr(fmt.Errorf("escpos: QR code module size must be between 1 and 16, got %d", moduleSize))
+		return e
+	}
+
+	var m byte
+	switch model {
+	case 1:
+		m = 49
+	case 2:
+		m = 50
+	default:
+		e.setErr(fmt.Errorf("escpos: unsupported QR code model %d", model))
+		return e
+	}
+
+	e.gsFn165(cnQRCode, 0x41, []byte{m, 0x00})    // select model
+	e.gsFn165(cnQRCode, 0x43, []byte{moduleSize}) // set module size
+	e.gsFn165(cnQRCode, 0x45, []byte{byte(ec)})   // set error correction level
+	e.storeSymbolData(cnQRCode, []byte(data))
+	return e.gsFn165(cnQRCode, fnPrintData, []byte{0x30})
+}
+
+// PDF417 prints data as a PDF417 barcode. columns is the number of data
+// columns (0 lets the printer choose automatically) and rows is the number
+// of rows (0 for automatic). Any validation error is recorded on e and can
+// be retrieved with Err.
+func (e *Escpos) PDF417(data string, columns, rows uint8) *Escpos {
+	if len(data) == 0 {
+		e.setErr(fmt.Errorf("escpos: PDF417 data must not be empty"))
+		return e
+	}
+
+	e.gsFn165(cnPDF417, 0x41, []byte{columns}) // set number of columns
+	e.gsFn165(cnPDF417, 0x42, []byte{rows})    // set number of rows
+	e.gsFn165(cnPDF417, 0x45, []byte{48, 48})  // set error correction level (m=48 level, n=48 level 0)
+	e.storeSymbolData(cnPDF417, []byte(data))
+	return e.gsFn165(cnPDF417, fnPrintData, []byte{0x30})
+}
+
+// DataMatrix prints data as a Data Matrix symbol using moduleSize dots per
+// module (1-16). Any validation error is recorded on e and can be retrieved
+// with Err.
+func (e *Escpos) DataMatrix(data string, moduleSize uint8) *Escpos {
+	if moduleSize < 1 || moduleSize > 16 {
+		e.setErr(fmt.Errorf("escpos: Data Matrix module size must be between 1 and 16, got %d", moduleSize))
+		return e
+	}
+
+	e.gsFn165(cnDataMatrix, 0x43, []byte{moduleSize}) // set module size
+	e.storeSymbolData(cnDataMatrix, []byte(data))
+	return e.gsFn165(cnDataMatrix, fnPrintData, []byte{0x30})
+}